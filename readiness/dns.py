@@ -0,0 +1,139 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""DNS queries sent to a given server."""
+
+import random
+import socket
+import struct
+
+# DNS record types, port and timeout (in seconds) of the DNS queries
+DNS_A = 1
+DNS_AAAA = 28
+DNS_PORT = 53
+DNS_TIMEOUT = 5
+
+
+def skip_name(message, offset):
+    """
+    Skip a possibly compressed name in a DNS message.
+
+    Args:
+        message (bytes): the DNS message.
+        offset (int): the offset of the name.
+
+    Returns:
+        the offset following the name
+    """
+    while True:
+        length = message[offset]
+        if length == 0:
+            return offset + 1
+        if length & 0xc0 == 0xc0:
+            # compression pointer, ending the name
+            return offset + 2
+        offset += length + 1
+
+
+def parse_response(response, query_id, record_type):
+    """
+    Extract the addresses of a DNS response.
+
+    Args:
+        response (bytes): the DNS response.
+        query_id (int): the ID of the query.
+        record_type (int): the record type of the query, DNS_A or DNS_AAAA.
+
+    Returns:
+        the addresses of the answers of the record type, none if the
+        response is not the one of the query or tells an error
+
+    Raises:
+        struct.error: if the response is truncated.
+    """
+    family = socket.AF_INET6 if record_type == DNS_AAAA else socket.AF_INET
+    (response_id, flags, questions,
+     answers) = struct.unpack("!4H", response[:8])
+    if response_id != query_id or flags & 0x000f:
+        return []
+    addresses = []
+    offset = 12
+    for _ in range(questions):
+        offset = skip_name(response, offset) + 4
+    for _ in range(answers):
+        offset = skip_name(response, offset)
+        answer_type, _, _, length = struct.unpack(
+            "!2HIH", response[offset:offset + 10])
+        offset += 10
+        if answer_type == record_type:
+            addresses.append(socket.inet_ntop(
+                family, response[offset:offset + length]))
+        offset += length
+    return addresses
+
+
+def build_query(query_id, name, record_type):
+    """
+    Build a recursive DNS query.
+
+    Args:
+        query_id (int): the ID of the query.
+        name (str): the name to resolve.
+        record_type (int): the record type, DNS_A or DNS_AAAA.
+
+    Returns:
+        the DNS query
+    """
+    question = b"".join(bytes([len(label)]) + label.encode()
+                        for label in name.rstrip(".").split(".")) + b"\0"
+    return (struct.pack("!6H", query_id, 0x0100, 1, 0, 0, 0) + question +
+            struct.pack("!2H", record_type, 1))
+
+
+def resolve(name, server, family=socket.AF_UNSPEC):
+    """
+    Resolve a name with a given DNS server.
+
+    The name is queried as is: search domains and ndots are not applied.
+
+    Args:
+        name (str): the name to resolve.
+        server (str): the IP address of the DNS server.
+        family (int): the address family of the addresses, AF_UNSPEC for
+            both.
+
+    Returns:
+        the IPv4 and IPv6 addresses of the name, only the ones of the
+        address family if given
+
+    Raises:
+        OSError: if the server does not answer.
+        struct.error: if a response is truncated.
+    """
+    server_family = socket.AF_INET6 if ":" in server else socket.AF_INET
+    addresses = []
+    for record_type, record_family in ((DNS_A, socket.AF_INET),
+                                       (DNS_AAAA, socket.AF_INET6)):
+        if family not in (socket.AF_UNSPEC, record_family):
+            continue
+        query_id = random.randint(0, 0xffff)
+        with socket.socket(server_family, socket.SOCK_DGRAM) as sock:
+            sock.settimeout(DNS_TIMEOUT)
+            sock.sendto(build_query(query_id, name, record_type),
+                        (server, DNS_PORT))
+            response = sock.recv(4096)
+        addresses.extend(parse_response(response, query_id, record_type))
+    return addresses
@@ -0,0 +1,156 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""gRPC health checking over cleartext HTTP/2."""
+
+import struct
+
+# connection preface, frame types and flags used, and statuses of the
+# health responses
+HTTP2_PREFACE = b"PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+HTTP2_DATA, HTTP2_HEADERS, HTTP2_RST_STREAM, HTTP2_SETTINGS = 0, 1, 3, 4
+HTTP2_PING, HTTP2_GOAWAY = 6, 7
+HTTP2_END_STREAM, HTTP2_ACK, HTTP2_END_HEADERS = 0x1, 0x1, 0x4
+GRPC_HEALTH_STATUSES = ("UNKNOWN", "SERVING", "NOT_SERVING",
+                        "SERVICE_UNKNOWN")
+
+
+def hpack_string(value):
+    """
+    Encode a string literal of an HPACK header block, without Huffman code.
+
+    Args:
+        value (str): the string.
+
+    Returns:
+        the encoded string
+    """
+    data = value.encode()
+    length = len(data)
+    if length < 0x7f:
+        return bytes([length]) + data
+    encoded = bytearray([0x7f])
+    length -= 0x7f
+    while length >= 0x80:
+        encoded.append(length & 0x7f | 0x80)
+        length >>= 7
+    encoded.append(length)
+    return bytes(encoded) + data
+
+
+def read_frame(connection):
+    """
+    Read an HTTP/2 frame.
+
+    Args:
+        connection (socket.socket): the connection.
+
+    Returns:
+        the type, the flags, the stream and the payload of the frame
+
+    Raises:
+        OSError: if the connection is closed before the end of the frame.
+    """
+    data = b""
+    size = 9
+    while len(data) < size:
+        chunk = connection.recv(size - len(data))
+        if not chunk:
+            raise OSError("connection closed")
+        data += chunk
+        if len(data) == 9:
+            size += int.from_bytes(data[:3], "big")
+    kind, flags, stream = struct.unpack(">BBI", data[3:9])
+    return kind, flags, stream & 0x7fffffff, data[9:]
+
+
+def frame(kind, flags, stream, payload=b""):
+    """
+    Build an HTTP/2 frame.
+
+    Args:
+        kind (int): the type of the frame.
+        flags (int): the flags of the frame.
+        stream (int): the stream of the frame, 0 for the connection.
+        payload (bytes): the payload of the frame.
+
+    Returns:
+        the frame
+    """
+    return (struct.pack(">I", len(payload))[1:] +
+            struct.pack(">BBI", kind, flags, stream) + payload)
+
+
+def grpc_health(connection, authority):
+    """
+    Return the health status of a gRPC server.
+
+    The Check method of the standard grpc.health.v1.Health service is
+    called for the server as a whole, the header block being made of
+    literals only so that no HPACK table is needed.
+
+    Args:
+        connection (socket.socket): the connection to the server.
+        authority (str): the server, as '<host>:<port>'.
+
+    Returns:
+        the status: UNKNOWN, SERVING, NOT_SERVING or SERVICE_UNKNOWN
+
+    Raises:
+        OSError: if the server closes the connection or resets the call.
+        ValueError: if the server does not tell its status.
+    """
+    headers = b"".join(
+        b"\x00" + hpack_string(name) + hpack_string(value)
+        for name, value in ((":method", "POST"), (":scheme", "http"),
+                            (":path", "/grpc.health.v1.Health/Check"),
+                            (":authority", authority),
+                            ("content-type", "application/grpc"),
+                            ("te", "trailers")))
+    # uncompressed and empty HealthCheckRequest
+    message = b"\x00\x00\x00\x00\x00"
+    response = b""
+    connection.sendall(
+        HTTP2_PREFACE + frame(HTTP2_SETTINGS, 0, 0) +
+        frame(HTTP2_HEADERS, HTTP2_END_HEADERS, 1, headers) +
+        frame(HTTP2_DATA, HTTP2_END_STREAM, 1, message))
+    while True:
+        kind, flags, stream, payload = read_frame(connection)
+        if kind == HTTP2_SETTINGS and not flags & HTTP2_ACK:
+            connection.sendall(frame(HTTP2_SETTINGS, HTTP2_ACK, 0))
+        elif kind == HTTP2_PING and not flags & HTTP2_ACK:
+            connection.sendall(frame(HTTP2_PING, HTTP2_ACK, 0, payload))
+        elif kind == HTTP2_GOAWAY:
+            raise OSError("connection refused by the server (GOAWAY)")
+        elif stream != 1:
+            continue
+        elif kind == HTTP2_RST_STREAM:
+            raise OSError("call reset by the server (error {})".format(
+                int.from_bytes(payload, "big")))
+        elif kind == HTTP2_DATA:
+            response += payload
+        if stream == 1 and flags & HTTP2_END_STREAM:
+            break
+    if len(response) < 5:
+        # e.g. a trailers-only response telling the call failed
+        raise ValueError("no health status in the response")
+    # HealthCheckResponse, its status being field 1, omitted when UNKNOWN
+    status = response[5:]
+    if status[:1] == b"\x08" and len(status) > 1:
+        code = status[1]
+        if code < len(GRPC_HEALTH_STATUSES):
+            return GRPC_HEALTH_STATUSES[code]
+    return GRPC_HEALTH_STATUSES[0]
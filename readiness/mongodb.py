@@ -0,0 +1,151 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""MongoDB commands over OP_MSG, with their BSON documents."""
+
+import struct
+
+# OP_MSG operation code, and the fixed size BSON types with their formats
+MONGODB_OP_MSG = 2013
+BSON_FIXED = {0x01: "<d", 0x08: "<?", 0x09: "<q", 0x10: "<i", 0x11: "<Q",
+              0x12: "<q"}
+
+
+def encode_bson(document):
+    """
+    Encode a flat document in BSON.
+
+    Args:
+        document (dict): the document, with boolean, integer or string
+            values.
+
+    Returns:
+        the encoded document
+    """
+    body = b""
+    for key, value in document.items():
+        name = key.encode() + b"\x00"
+        if isinstance(value, bool):
+            body += b"\x08" + name + (b"\x01" if value else b"\x00")
+        elif isinstance(value, int):
+            body += b"\x10" + name + struct.pack("<i", value)
+        else:
+            data = str(value).encode() + b"\x00"
+            body += b"\x02" + name + struct.pack("<i", len(data)) + data
+    return struct.pack("<i", len(body) + 5) + body + b"\x00"
+
+
+def decode_bson(data, offset=0):
+    """
+    Decode a BSON document.
+
+    Args:
+        data (bytes): the data holding the document.
+        offset (int): the offset of the document in the data.
+
+    Returns:
+        the document, arrays being decoded as lists, binaries and decimals
+        as bytes and object IDs as hexadecimal strings
+
+    Raises:
+        ValueError: if the document holds an unsupported type.
+        struct.error: if the document is truncated.
+    """
+    end = offset + struct.unpack_from("<i", data, offset)[0] - 1
+    position = offset + 4
+    document = {}
+    while position < end:
+        kind = data[position]
+        name_end = data.index(b"\x00", position + 1)
+        name = data[position + 1:name_end].decode()
+        position = name_end + 1
+        if kind in BSON_FIXED:
+            value = struct.unpack_from(BSON_FIXED[kind], data, position)[0]
+            position += struct.calcsize(BSON_FIXED[kind])
+        elif kind == 0x02:
+            size = struct.unpack_from("<i", data, position)[0]
+            value = data[position + 4:position + 3 + size].decode()
+            position += 4 + size
+        elif kind in (0x03, 0x04):
+            value = decode_bson(data, position)
+            if kind == 0x04:
+                value = list(value.values())
+            position += struct.unpack_from("<i", data, position)[0]
+        elif kind == 0x05:
+            size = struct.unpack_from("<i", data, position)[0]
+            value = data[position + 5:position + 5 + size]
+            position += 5 + size
+        elif kind == 0x07:
+            value = data[position:position + 12].hex()
+            position += 12
+        elif kind == 0x0A:
+            value = None
+        elif kind == 0x13:
+            value = data[position:position + 16]
+            position += 16
+        else:
+            raise ValueError("unsupported BSON type {:#x}".format(kind))
+        document[name] = value
+    return document
+
+
+def receive(connection, size):
+    """
+    Receive an exact amount of data from a connection.
+
+    Args:
+        connection (socket.socket): the connection.
+        size (int): the amount of data to receive.
+
+    Returns:
+        the received data
+
+    Raises:
+        OSError: if the connection is closed before.
+    """
+    data = b""
+    while len(data) < size:
+        chunk = connection.recv(size - len(data))
+        if not chunk:
+            raise OSError("connection closed by the server")
+        data += chunk
+    return data
+
+
+def command(connection, document):
+    """
+    Run a command on a MongoDB server, without authentication.
+
+    Args:
+        connection (socket.socket): the connection to the server.
+        document (dict): the command, run on the admin database.
+
+    Returns:
+        the response of the server
+
+    Raises:
+        OSError: if the server closes the connection.
+        ValueError: if the response is malformed.
+        struct.error: if the response is truncated.
+    """
+    body = encode_bson(dict(document, **{"$db": "admin"}))
+    # OP_MSG header, flags and body section
+    connection.sendall(struct.pack("<iiiiI", 21 + len(body), 1, 0,
+                                   MONGODB_OP_MSG, 0) + b"\x00" + body)
+    length = struct.unpack("<i", receive(connection, 4))[0]
+    response = receive(connection, length - 4)
+    # skip the rest of the header, the flags and the section kind
+    return decode_bson(response, 17)
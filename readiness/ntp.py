@@ -0,0 +1,84 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""SNTP queries of the offset of the local clock."""
+
+import socket
+import struct
+import time
+
+# timeout (in seconds) of the NTP queries, size of their packets, and
+# seconds from the NTP epoch (1900) to the Unix one (1970)
+NTP_TIMEOUT = 5
+NTP_PACKET_SIZE = 48
+NTP_EPOCH_OFFSET = 2208988800
+
+# SNTP client request: no leap indicator, version 3, client mode
+NTP_REQUEST = b"\x1b" + (NTP_PACKET_SIZE - 1) * b"\0"
+
+
+def parse_offset(response, sent, received):
+    """
+    Compute the offset of the local clock from an NTP answer.
+
+    Args:
+        response (bytes): the NTP answer.
+        sent (float): the local time the request was sent at.
+        received (float): the local time the answer was received at.
+
+    Returns:
+        the offset of the server clock from the local one in seconds
+
+    Raises:
+        ValueError: if the answer is too short.
+    """
+    if len(response) < NTP_PACKET_SIZE:
+        raise ValueError("short NTP answer of {} bytes".format(
+            len(response)))
+    server_received, server_sent = (
+        seconds + fraction / 2 ** 32 - NTP_EPOCH_OFFSET
+        for seconds, fraction in struct.iter_unpack("!II",
+                                                    response[32:48]))
+    return ((server_received - sent) + (server_sent - received)) / 2
+
+
+def get_offset(host, port, family=socket.AF_UNSPEC):
+    """
+    Query the offset of the local clock from an NTP server.
+
+    The server is queried on its first address in the address family.
+
+    Args:
+        host (str): the host name or IP address of the server.
+        port (int): the port of the server.
+        family (int): the address family, AF_UNSPEC for any.
+
+    Returns:
+        the offset of the server clock from the local one in seconds
+
+    Raises:
+        OSError: if the server does not answer.
+        ValueError: if the answer is malformed.
+    """
+    family, kind, protocol, _, address = socket.getaddrinfo(
+        host, port, family, socket.SOCK_DGRAM)[0]
+    with socket.socket(family, kind, protocol) as sock:
+        sock.settimeout(NTP_TIMEOUT)
+        sent = time.time()
+        sock.sendto(NTP_REQUEST, address)
+        response = sock.recv(512)
+        received = time.time()
+    return parse_offset(response, sent, received)
@@ -0,0 +1,69 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""AWS Signature Version 4 of the S3 requests."""
+
+import datetime
+import hashlib
+import hmac
+import urllib.parse
+
+
+def sign_s3_request(method, url, access_key, secret_key, region,
+                    headers=None, now=None):
+    """
+    Sign an S3 request without payload with AWS Signature Version 4.
+
+    Args:
+        method (str): the HTTP method.
+        url (str): the URL of the request, without query.
+        access_key (str): the access key.
+        secret_key (str): the secret key.
+        region (str): the region of the endpoint.
+        headers (dict): other headers of the request to sign, e.g. Range.
+        now (datetime.datetime): the UTC time of the request, the current
+            one by default.
+
+    Returns:
+        the headers of the request, with the ones authenticating it
+    """
+    parts = urllib.parse.urlsplit(url)
+    now = now or datetime.datetime.utcnow()
+    amz_date = now.strftime("%Y%m%dT%H%M%SZ")
+    scope = "{}/{}/s3/aws4_request".format(now.strftime("%Y%m%d"), region)
+    payload_hash = hashlib.sha256(b"").hexdigest()
+    headers = {name.lower(): value.strip()
+               for name, value in (headers or {}).items()}
+    headers.update({"host": parts.netloc,
+                    "x-amz-content-sha256": payload_hash,
+                    "x-amz-date": amz_date})
+    signed_headers = ";".join(sorted(headers))
+    canonical_request = "\n".join(
+        [method, urllib.parse.quote(parts.path or "/"), ""] +
+        ["{}:{}".format(name, headers[name]) for name in sorted(headers)] +
+        ["", signed_headers, payload_hash])
+    string_to_sign = "\n".join([
+        "AWS4-HMAC-SHA256", amz_date, scope,
+        hashlib.sha256(canonical_request.encode()).hexdigest()])
+    key = ("AWS4" + secret_key).encode()
+    for part in scope.split("/"):
+        key = hmac.new(key, part.encode(), hashlib.sha256).digest()
+    signature = hmac.new(key, string_to_sign.encode(),
+                         hashlib.sha256).hexdigest()
+    headers["Authorization"] = (
+        "AWS4-HMAC-SHA256 Credential={}/{}, SignedHeaders={}, "
+        "Signature={}".format(access_key, scope, signed_headers, signature))
+    return headers
@@ -0,0 +1,152 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""X.509 certificates and the PEM bundles and JKS truststores holding them."""
+
+import base64
+import datetime
+import re
+import struct
+
+# DER encoding of the OID of the common name attribute of X.509 names
+X509_COMMON_NAME = bytes([0x55, 0x04, 0x03])
+
+# magic number of the JKS truststores and the tags of their entries
+JKS_MAGIC = bytes([0xfe, 0xed, 0xfe, 0xed])
+JKS_PRIVATE_KEY = 1
+JKS_TRUSTED_CERTIFICATE = 2
+
+
+def read_der(data, offset):
+    """
+    Read the header of a DER element.
+
+    Args:
+        data (bytes): the DER encoding.
+        offset (int): the offset of the element.
+
+    Returns:
+        the tag, the offset of the content and the end of the element
+
+    Raises:
+        ValueError: if the element is truncated.
+    """
+    tag, length = data[offset], data[offset + 1]
+    offset += 2
+    if length & 0x80:
+        size = length & 0x7f
+        length = int.from_bytes(data[offset:offset + size], "big")
+        offset += size
+    if offset + length > len(data):
+        raise ValueError("truncated DER element")
+    return tag, offset, offset + length
+
+
+def parse_certificate(der):
+    """
+    Extract the common name and the validity of an X.509 certificate.
+
+    Args:
+        der (bytes): the DER encoding of the certificate.
+
+    Returns:
+        the common name of the subject, None if it has none, and the start
+        and end of the validity as timestamps
+
+    Raises:
+        ValueError: if the certificate is malformed.
+    """
+    _, offset, _ = read_der(der, 0)
+    _, offset, _ = read_der(der, offset)
+    fields = []
+    while len(fields) < 5:
+        tag, start, end = read_der(der, offset)
+        # the version is an optional explicitly tagged field
+        if tag != 0xa0 or fields:
+            fields.append((start, end))
+        offset = end
+    # serial number, signature algorithm, issuer, validity and subject
+    _, _, _, (start, end), (subject, subject_end) = fields
+    validity = []
+    while start < end:
+        tag, content, start = read_der(der, start)
+        value = der[content:start].decode("ascii")
+        # UTCTime years are 19xx from 50 on
+        if tag == 0x17:
+            value = ("19" if value[:2] >= "50" else "20") + value
+        validity.append(datetime.datetime.strptime(
+            value, "%Y%m%d%H%M%SZ").replace(
+                tzinfo=datetime.timezone.utc).timestamp())
+    common_name = None
+    while subject < subject_end:
+        _, attributes, subject = read_der(der, subject)
+        _, attribute, _ = read_der(der, attributes)
+        _, oid, oid_end = read_der(der, attribute)
+        if der[oid:oid_end] == X509_COMMON_NAME:
+            _, value, value_end = read_der(der, oid_end)
+            common_name = der[value:value_end].decode("utf-8", "replace")
+    return common_name, validity[0], validity[1]
+
+
+def read_truststore_certificates(store):
+    """
+    Read the certificates of a PEM bundle or a JKS truststore.
+
+    The trusted certificates and the certificate chains of the key entries
+    of a JKS truststore are read, the integrity of the store is not checked
+    as no password is needed for that.
+
+    Args:
+        store (bytes): the content of the truststore.
+
+    Returns:
+        the DER encodings of the certificates
+
+    Raises:
+        ValueError: if the truststore is malformed.
+        struct.error: if the JKS truststore is truncated.
+    """
+    if store[:4] != JKS_MAGIC:
+        return [base64.b64decode(block) for block in re.findall(
+            rb"-----BEGIN CERTIFICATE-----(.*?)-----END CERTIFICATE-----",
+            store, re.DOTALL)]
+    version, count = struct.unpack_from("!II", store, 4)
+    offset = 12
+    certificates = []
+
+    def read_certificate(offset):
+        if version == 2:
+            # certificate type, e.g. X.509
+            offset += 2 + struct.unpack_from("!H", store, offset)[0]
+        length = struct.unpack_from("!I", store, offset)[0]
+        certificates.append(store[offset + 4:offset + 4 + length])
+        return offset + 4 + length
+
+    for _ in range(count):
+        tag = struct.unpack_from("!I", store, offset)[0]
+        # alias and creation date
+        offset += 4 + 2 + struct.unpack_from("!H", store, offset + 4)[0] + 8
+        if tag == JKS_TRUSTED_CERTIFICATE:
+            offset = read_certificate(offset)
+        elif tag == JKS_PRIVATE_KEY:
+            offset += 4 + struct.unpack_from("!I", store, offset)[0]
+            chain = struct.unpack_from("!I", store, offset)[0]
+            offset += 4
+            for _ in range(chain):
+                offset = read_certificate(offset)
+        else:
+            raise ValueError("unsupported JKS entry type {}".format(tag))
+    return certificates
@@ -0,0 +1,64 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""ZooKeeper four-letter words."""
+
+
+def parse_mode(response):
+    """
+    Extract the mode of a ZooKeeper server from its srvr response.
+
+    Args:
+        response (bytes): the response to srvr.
+
+    Returns:
+        the mode of the server: leader, follower, observer or standalone
+
+    Raises:
+        ValueError: if the response does not tell the mode.
+    """
+    lines = response.decode(errors="replace").splitlines()
+    for line in lines:
+        if line.startswith("Mode:"):
+            return line.partition(":")[2].strip()
+    # e.g. not currently serving requests, or srvr not allowed
+    raise ValueError(lines[0] if lines else "empty response")
+
+
+def server_mode(connection):
+    """
+    Return the mode of a ZooKeeper server.
+
+    The srvr four-letter word is used, as it is allowed by default, the
+    server closing the connection after its response.
+
+    Args:
+        connection (socket.socket): the connection to the server.
+
+    Returns:
+        the mode of the server: leader, follower, observer or standalone
+
+    Raises:
+        OSError: if the connection fails.
+        ValueError: if the server does not tell its mode.
+    """
+    response = b""
+    connection.sendall(b"srvr")
+    chunk = connection.recv(4096)
+    while chunk:
+        response += chunk
+        chunk = connection.recv(4096)
+    return parse_mode(response)
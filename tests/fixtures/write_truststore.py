@@ -0,0 +1,103 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""
+Write the JKS truststore of the tests, protected by the changeit password.
+
+The truststore holds the onap-ca certificate of ca.pem as a trusted
+certificate and the sdnc key of sdnc.p8 as a key entry, its chain being the
+sdnc and onap-ca certificates of bundle.pem.
+"""
+
+import base64
+import hashlib
+import os
+import re
+import struct
+import sys
+
+PASSWORD = "changeit".encode("utf-16-be")
+
+# OID of the key protection algorithm of the JKS key entries
+KEY_PROTECTOR = bytes([0x2b, 0x06, 0x01, 0x04, 0x01, 0x2a, 0x02, 0x11, 0x01,
+                       0x01])
+
+# creation date of the entries, in milliseconds
+CREATED = 1760486400000
+
+
+def read_pem(path):
+    """Read the DER encodings of the certificates of a PEM bundle."""
+    with open(path, "rb") as pem:
+        return [base64.b64decode(block) for block in re.findall(
+            rb"-----BEGIN CERTIFICATE-----(.*?)-----END CERTIFICATE-----",
+            pem.read(), re.DOTALL)]
+
+
+def utf(text):
+    """Encode a string as Java modified UTF-8, ASCII only."""
+    return struct.pack("!H", len(text)) + text.encode()
+
+
+def der(tag, content):
+    """Encode a DER element."""
+    length = len(content)
+    if length < 0x80:
+        return bytes([tag, length]) + content
+    size = (length.bit_length() + 7) // 8
+    return bytes([tag, 0x80 | size]) + length.to_bytes(size, "big") + content
+
+
+def protect(key):
+    """Protect a PKCS#8 key as the JKS key entries are."""
+    salt = os.urandom(20)
+    digest, stream = salt, b""
+    while len(stream) < len(key):
+        digest = hashlib.sha1(PASSWORD + digest).digest()
+        stream += digest
+    encrypted = (salt + bytes(a ^ b for a, b in zip(key, stream)) +
+                 hashlib.sha1(PASSWORD + key).digest())
+    return der(0x30, der(0x30, der(0x06, KEY_PROTECTOR) + der(0x05, b"")) +
+               der(0x04, encrypted))
+
+
+def certificate(data):
+    """Encode a certificate of a version 2 JKS."""
+    return utf("X.509") + struct.pack("!I", len(data)) + data
+
+
+def main(directory):
+    """Write truststore.jks from the fixtures of a directory."""
+    ca_certificate, = read_pem(os.path.join(directory, "ca.pem"))
+    chain = read_pem(os.path.join(directory, "bundle.pem"))
+    with open(os.path.join(directory, "sdnc.p8"), "rb") as p8:
+        key = protect(p8.read())
+    created = struct.pack("!q", CREATED)
+    store = (bytes([0xfe, 0xed, 0xfe, 0xed]) + struct.pack("!II", 2, 2) +
+             struct.pack("!I", 2) + utf("onap-ca") + created +
+             certificate(ca_certificate) +
+             struct.pack("!I", 1) + utf("sdnc") + created +
+             struct.pack("!I", len(key)) + key +
+             struct.pack("!I", len(chain)) +
+             b"".join(certificate(data) for data in chain))
+    store += hashlib.sha1(PASSWORD + b"Mighty Aphrodite" + store).digest()
+    with open(os.path.join(directory, "truststore.jks"), "wb") as jks:
+        jks.write(store)
+
+
+if __name__ == "__main__":
+    main(sys.argv[1] if len(sys.argv) > 1 else os.path.dirname(__file__))
@@ -0,0 +1,106 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Tests of the DNS queries."""
+
+import socket
+import struct
+import threading
+import unittest
+from unittest import mock
+
+from readiness import dns
+
+
+def answer(query, records):
+    """Build the response to a query, with answers pointing to its name."""
+    query_id = struct.unpack("!H", query[:2])[0]
+    response = struct.pack("!6H", query_id, 0x8180, 1, len(records), 0, 0)
+    response += query[12:]
+    for record_type, address in records:
+        family = socket.AF_INET6 if record_type == dns.DNS_AAAA else (
+            socket.AF_INET)
+        data = socket.inet_pton(family, address)
+        response += b"\xc0\x0c" + struct.pack("!2HIH", record_type, 1, 30,
+                                              len(data)) + data
+    return response
+
+
+class TestDns(unittest.TestCase):
+    """Tests of the encoding and decoding of the DNS messages."""
+
+    def test_build_query(self):
+        """The name is encoded as labels, recursion desired."""
+        query = dns.build_query(0x1234, "so.onap.", dns.DNS_A)
+        self.assertEqual(query, bytes.fromhex("123401000001000000000000") +
+                         b"\x02so\x04onap\x00" + b"\x00\x01\x00\x01")
+
+    def test_skip_name(self):
+        """Labels and compression pointers end the names."""
+        self.assertEqual(dns.skip_name(b"\x02so\x04onap\x00", 0), 9)
+        self.assertEqual(dns.skip_name(b"\x02so\xc0\x0c", 0), 5)
+
+    def test_parse_response(self):
+        """The answers of the queried type are extracted."""
+        query = dns.build_query(7, "so", dns.DNS_A)
+        response = answer(query, [(dns.DNS_A, "10.1.2.3"),
+                                  (5, "10.0.0.1"),
+                                  (dns.DNS_A, "10.1.2.4")])
+        self.assertEqual(dns.parse_response(response, 7, dns.DNS_A),
+                         ["10.1.2.3", "10.1.2.4"])
+        self.assertEqual(dns.parse_response(response, 8, dns.DNS_A), [])
+
+    def test_parse_error(self):
+        """A response telling an error has no addresses."""
+        query = dns.build_query(7, "so", dns.DNS_A)
+        response = bytearray(answer(query, [(dns.DNS_A, "10.1.2.3")]))
+        # NXDOMAIN
+        response[3] |= 3
+        self.assertEqual(dns.parse_response(bytes(response), 7, dns.DNS_A),
+                         [])
+
+    def test_parse_truncated(self):
+        """A truncated response is an error."""
+        with self.assertRaises(struct.error):
+            dns.parse_response(b"\x00\x07", 7, dns.DNS_A)
+
+    def test_resolve(self):
+        """Both address families are queried unless one is given."""
+        server = socket.socket(socket.AF_INET, socket.SOCK_DGRAM)
+        self.addCleanup(server.close)
+        server.bind(("127.0.0.1", 0))
+        records = {dns.DNS_A: "10.1.2.3", dns.DNS_AAAA: "fd00::3"}
+
+        def serve(count):
+            for _ in range(count):
+                query, client = server.recvfrom(512)
+                record_type = struct.unpack("!H", query[-4:-2])[0]
+                server.sendto(answer(query, [(record_type,
+                                              records[record_type])]),
+                              client)
+
+        with mock.patch.object(dns, "DNS_PORT", server.getsockname()[1]):
+            thread = threading.Thread(target=serve, args=(3,))
+            thread.start()
+            self.assertEqual(dns.resolve("so", "127.0.0.1"),
+                             ["10.1.2.3", "fd00::3"])
+            self.assertEqual(dns.resolve("so", "127.0.0.1",
+                                         socket.AF_INET6), ["fd00::3"])
+            thread.join()
+
+
+if __name__ == "__main__":
+    unittest.main()
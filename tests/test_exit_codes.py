@@ -0,0 +1,112 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Tests of the exit codes of the timed out checks."""
+
+import unittest
+from unittest import mock
+
+import ready
+
+
+class TestExitCodes(unittest.TestCase):
+    """Tests of the exit codes telling the failures apart."""
+
+    def setUp(self):
+        ready.use_options(ready.Options())
+        for state in (ready.check_states, ready.exit_codes,
+                      ready.failing_api_calls, ready.evictions):
+            patcher = mock.patch.dict(state, clear=True)
+            patcher.start()
+            self.addCleanup(patcher.stop)
+        for name in ("failed_checks", "pending_checks"):
+            patcher = mock.patch.object(ready, name, [])
+            patcher.start()
+            self.addCleanup(patcher.stop)
+
+    def time_out(self, check, missing=False):
+        with self.assertRaises(SystemExit) as raised:
+            ready.time_out(check, missing)
+        return raised.exception.code
+
+    def test_timeout(self):
+        """A check not ready in time exits with 3."""
+        self.assertEqual(self.time_out("so"), ready.EXIT_TIMEOUT)
+        self.assertEqual(ready.check_states["so"]["state"], "timed out")
+
+    def test_not_found(self):
+        """A check whose resource was never found exits with 4."""
+        self.assertEqual(self.time_out("so", missing=True),
+                         ready.EXIT_NOT_FOUND)
+
+    def test_not_found_waiting(self):
+        """A check whose resource lookup gives a 404 exits with 4."""
+        ready.options.timeout = -1
+        exists = mock.Mock(side_effect=ready.ApiException(status=404))
+        with self.assertRaises(SystemExit) as raised:
+            ready.wait_for("so", lambda *_: False, exists)
+        self.assertEqual(raised.exception.code, ready.EXIT_NOT_FOUND)
+        exists.assert_called_once_with("so", ready.namespace)
+
+    def test_api_failing(self):
+        """A check whose API calls kept failing exits with 5."""
+        ready.failing_api_calls["so"] = "Forbidden"
+        self.assertEqual(self.time_out("so", missing=True), ready.EXIT_API)
+
+    def test_retryable(self):
+        """The retryable exit code of the options replaces the others."""
+        ready.options.retryable_exit = 75
+        with mock.patch.object(ready, "start_cooldown") as start_cooldown:
+            self.assertEqual(self.time_out("so"), 75)
+        start_cooldown.assert_called_once()
+
+    def test_wait_for_all(self):
+        """The run goes on with the next checks when waiting for all."""
+        ready.options.failure_mode = "wait-for-all"
+        ready.pending_checks.extend(["so", "aai"])
+        ready.time_out("so")
+        self.assertEqual(ready.failed_checks, ["so"])
+        self.assertEqual(ready.pending_checks, ["aai"])
+        self.assertEqual(ready.exit_codes["so"], ready.EXIT_TIMEOUT)
+
+    def test_failed_wait_for_all(self):
+        """A failed check is no longer pending when waiting for all."""
+        ready.options.failure_mode = "wait-for-all"
+        ready.pending_checks.extend(["so", "aai"])
+        ready.fail_check("so", "check.failed", "so")
+        self.assertEqual(ready.failed_checks, ["so"])
+        self.assertEqual(ready.pending_checks, ["aai"])
+        self.assertEqual(ready.check_states["so"]["state"], "failed")
+
+    def test_not_ready_once(self):
+        """A check not ready yet when checked once exits with 6."""
+        ready.options.container_names = ["so", "aai"]
+        evaluations = [ready.Evaluation(True, None, None),
+                       ready.Evaluation(False, 10, "not ready yet")]
+        with mock.patch.object(ready, "evaluate",
+                               side_effect=evaluations) as evaluate:
+            self.assertEqual(ready.check_once(), ready.EXIT_NOT_READY)
+        self.assertEqual(evaluate.call_count, 2)
+
+    def test_abort(self):
+        """The exit code is given to the exit function."""
+        exit_process = mock.Mock()
+        ready.abort(False, ready.EXIT_FAILED, exit_process)
+        exit_process.assert_called_once_with(ready.EXIT_FAILED)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,177 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Tests of the boolean expressions of checks and of their evaluation."""
+
+import threading
+import time
+import unittest
+from unittest import mock
+
+import ready
+
+
+class TestParseExpression(unittest.TestCase):
+    """Tests of the parsing of the expressions."""
+
+    def test_precedence(self):
+        """&& binds tighter than ||."""
+        self.assertEqual(
+            ready.parse_expression("job:a || job:b && service:c"),
+            ("or", [("term", "job", "a"),
+                    ("and", [("term", "job", "b"),
+                             ("term", "service", "c")])]))
+
+    def test_group(self):
+        """A parenthesized group may be given a timeout in minutes."""
+        self.assertEqual(
+            ready.parse_expression("service:a && (job:b || job:c)@5"),
+            ("and", [("term", "service", "a"),
+                     ("group", ("or", [("term", "job", "b"),
+                                       ("term", "job", "c")]), 5.0)]))
+
+    def test_malformed(self):
+        """Unbalanced, truncated and unknown terms are rejected."""
+        for expression in ("(job:a", "job:a &&", "job:a job:b", "nope:a",
+                           "job:a & job:b", "job"):
+            with self.assertRaises(ValueError, msg=expression):
+                ready.parse_expression(expression)
+
+
+class TestEvaluateExpression(unittest.TestCase):
+    """Tests of the evaluation of the expressions."""
+
+    def setUp(self):
+        self.ready_names = set()
+        self.calls = []
+
+        def check(name, namespace):
+            self.calls.append(name)
+            return name in self.ready_names
+
+        patcher = mock.patch.dict(ready.TERMS, {"job": check})
+        patcher.start()
+        self.addCleanup(patcher.stop)
+        ready.use_options(ready.Options())
+
+    def evaluate(self, expression, started=None, ready_terms=None):
+        return ready.evaluate_expression(
+            ready.parse_expression(expression),
+            time.time() if started is None else started,
+            set() if ready_terms is None else ready_terms, "onap", ("x",))
+
+    def test_and_or(self):
+        """The operands are combined as booleans."""
+        self.ready_names = {"a"}
+        self.assertIs(self.evaluate("job:a && job:b"), False)
+        self.assertIs(self.evaluate("job:a || job:b"), True)
+        self.assertIs(self.evaluate("(job:b || job:a) && job:a"), True)
+
+    def test_short_circuit(self):
+        """The evaluation stops as soon as the result is known."""
+        self.ready_names = {"a"}
+        self.evaluate("job:a || job:b")
+        self.assertEqual(self.calls, ["a"])
+
+    def test_ready_terms_not_checked_again(self):
+        """The terms once ready are remembered."""
+        ready_terms = set()
+        self.ready_names = {"a"}
+        self.evaluate("job:a && job:b", ready_terms=ready_terms)
+        self.assertEqual(ready_terms, {("job", "a")})
+        self.calls.clear()
+        self.ready_names = {"b"}
+        self.assertIs(self.evaluate("job:a && job:b",
+                                    ready_terms=ready_terms), True)
+        self.assertEqual(self.calls, ["b"])
+
+    def test_group_timeout(self):
+        """A group not ready in time fails the expression."""
+        started = time.time() - 120
+        self.assertEqual(self.evaluate("(job:a)@1", started=started),
+                         ready.FAILED)
+        self.assertEqual(self.evaluate("job:b && (job:a)@1",
+                                       started=started), ready.FAILED)
+        self.ready_names = {"b"}
+        self.assertIs(self.evaluate("job:b || (job:a)@1", started=started),
+                      True)
+        self.assertIs(self.evaluate("(job:b)@1", started=started), True)
+
+    def test_delay_gated(self):
+        """A delay starts once the operands of && before it are ready."""
+        with mock.patch.dict(ready.delay_starts, clear=True):
+            self.assertIs(self.evaluate("job:a && (delay:30s)@5"), False)
+            self.assertIs(self.evaluate("job:a && (job:b || delay:30s)"),
+                          False)
+            self.assertEqual(ready.delay_starts, {})
+            self.ready_names = {"a"}
+            self.evaluate("job:a && (delay:30s)@5")
+            self.assertEqual(list(ready.delay_starts), [("x", 1, 0)])
+
+    def test_delay_per_position(self):
+        """The same delay at several positions starts at each of them."""
+        with mock.patch.dict(ready.delay_starts, clear=True):
+            ready.delay_starts[("x", 0)] = time.time() - 60
+            self.assertIs(self.evaluate("delay:30s || delay:30s"), True)
+            self.assertIs(self.evaluate("delay:30s && delay:30s"), False)
+            self.assertEqual(set(ready.delay_starts), {("x", 0), ("x", 1)})
+
+
+class TestEvaluate(unittest.TestCase):
+    """Tests of the single evaluations of the checks, as a library."""
+
+    def setUp(self):
+        ready.use_options(ready.Options())
+        patcher = mock.patch.dict(ready.check_states, clear=True)
+        patcher.start()
+        self.addCleanup(patcher.stop)
+
+    def test_failed(self):
+        """A check that can never be ready is failed, without exiting."""
+        def check(name, namespace):
+            ready.fail_check(name, "pipeline.failed", name, "Failed")
+            ready.log.info("after the failure")
+            return False
+
+        with mock.patch.dict(ready.TERMS, {"job": check}):
+            evaluation = ready.evaluate("a", "onap", ready.Options(), "job")
+        self.assertEqual(evaluation, ready.Evaluation(
+            False, None, ready.MESSAGES["pipeline.failed"] % ("a", "Failed"),
+            failed=True))
+        self.assertEqual(ready.check_states, {})
+
+    def test_options_of_thread(self):
+        """The checkers read the options given in their thread only."""
+        seen = []
+
+        def check(name, namespace):
+            seen.append(ready.options.poll_delay)
+            return True
+
+        options = ready.Options()
+        options.poll_delay = 42
+        with mock.patch.dict(ready.TERMS, {"job": check}):
+            thread = threading.Thread(target=ready.evaluate,
+                                      args=("a", "onap", options, "job"))
+            thread.start()
+            thread.join()
+            check("a", "onap")
+        self.assertEqual(seen, [42, ready.Options().poll_delay])
+        self.assertIsNone(ready.current.options)
+
+
+if __name__ == "__main__":
+    unittest.main()
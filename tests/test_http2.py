@@ -0,0 +1,221 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Tests of the gRPC health checking over cleartext HTTP/2."""
+
+import socket
+import threading
+import unittest
+
+from readiness import http2
+
+# response headers: indexed :status 200, and a literal content-type
+RESPONSE_HEADERS = bytes.fromhex("88") + b"\x00" + http2.hpack_string(
+    "content-type") + http2.hpack_string("application/grpc")
+
+# trailers: grpc-status 0
+TRAILERS = b"\x00" + http2.hpack_string("grpc-status") + (
+    http2.hpack_string("0"))
+
+
+def health_response(status):
+    """Build a length-prefixed HealthCheckResponse."""
+    message = b"" if status is None else bytes([0x08, status])
+    return b"\x00" + len(message).to_bytes(4, "big") + message
+
+
+class FakeServer:
+    """gRPC server answering a call with given frames, on a socket pair.
+
+    The frames received by the server are recorded, up to the end of the
+    connection.
+    """
+
+    def __init__(self, test, frames):
+        self.connection, server = socket.socketpair()
+        test.addCleanup(self.connection.close)
+        self.frames = frames
+        self.received = []
+        self.thread = threading.Thread(target=self.serve, args=(server,))
+        self.thread.start()
+
+    def serve(self, server):
+        with server:
+            preface = b""
+            while len(preface) < len(http2.HTTP2_PREFACE):
+                preface += server.recv(len(http2.HTTP2_PREFACE) -
+                                       len(preface))
+            self.received.append(preface)
+            # settings, headers and data of the call
+            for _ in range(3):
+                self.received.append(http2.read_frame(server))
+            server.sendall(b"".join(self.frames))
+            server.shutdown(socket.SHUT_WR)
+            try:
+                while True:
+                    self.received.append(http2.read_frame(server))
+            except OSError:
+                pass
+
+    def check(self):
+        try:
+            return http2.grpc_health(self.connection, "cds:9111")
+        finally:
+            self.connection.shutdown(socket.SHUT_WR)
+            self.thread.join()
+
+
+class TestHpack(unittest.TestCase):
+    """Tests of the HPACK string literals."""
+
+    def test_short(self):
+        """Short strings have their length in the first byte."""
+        self.assertEqual(http2.hpack_string("te"), b"\x02te")
+        self.assertEqual(http2.hpack_string(""), b"\x00")
+
+    def test_long(self):
+        """Long lengths continue on 7 bits integers."""
+        self.assertEqual(http2.hpack_string("a" * 126), b"\x7e" + b"a" * 126)
+        self.assertEqual(http2.hpack_string("a" * 127),
+                         b"\x7f\x00" + b"a" * 127)
+        self.assertEqual(http2.hpack_string("a" * 300),
+                         b"\x7f\xad\x01" + b"a" * 300)
+
+
+class TestFrames(unittest.TestCase):
+    """Tests of the encoding and decoding of the frames."""
+
+    def test_frame(self):
+        """The header tells the length, type, flags and stream."""
+        self.assertEqual(
+            http2.frame(http2.HTTP2_DATA, http2.HTTP2_END_STREAM, 1, b"ab"),
+            bytes.fromhex("000002000100000001") + b"ab")
+        self.assertEqual(http2.frame(http2.HTTP2_SETTINGS, 0, 0),
+                         bytes.fromhex("000000040000000000"))
+
+    def test_read_frame(self):
+        """Frames split across reads are reassembled."""
+        client, server = socket.socketpair()
+        with client, server:
+            data = http2.frame(http2.HTTP2_HEADERS, http2.HTTP2_END_HEADERS,
+                               0x80000003, b"xyz")
+            for byte in data:
+                server.sendall(bytes([byte]))
+            self.assertEqual(http2.read_frame(client),
+                             (http2.HTTP2_HEADERS, http2.HTTP2_END_HEADERS,
+                              3, b"xyz"))
+            server.sendall(data[:5])
+            server.close()
+            with self.assertRaises(OSError):
+                http2.read_frame(client)
+
+
+class TestGrpcHealth(unittest.TestCase):
+    """Tests of the Check calls of the health service."""
+
+    def test_request(self):
+        """The call is made of literal headers and an empty request."""
+        server = FakeServer(self, [
+            http2.frame(http2.HTTP2_HEADERS, http2.HTTP2_END_HEADERS, 1,
+                        RESPONSE_HEADERS),
+            http2.frame(http2.HTTP2_DATA, 0, 1, health_response(1)),
+            http2.frame(http2.HTTP2_HEADERS, http2.HTTP2_END_HEADERS |
+                        http2.HTTP2_END_STREAM, 1, TRAILERS)])
+        self.assertEqual(server.check(), "SERVING")
+        preface, settings, headers, data = server.received
+        self.assertEqual(preface, http2.HTTP2_PREFACE)
+        self.assertEqual(settings, (http2.HTTP2_SETTINGS, 0, 0, b""))
+        self.assertEqual(headers[:3], (http2.HTTP2_HEADERS,
+                                       http2.HTTP2_END_HEADERS, 1))
+        self.assertEqual(headers[3], b"".join(
+            b"\x00" + http2.hpack_string(name) + http2.hpack_string(value)
+            for name, value in (
+                (":method", "POST"), (":scheme", "http"),
+                (":path", "/grpc.health.v1.Health/Check"),
+                (":authority", "cds:9111"),
+                ("content-type", "application/grpc"), ("te", "trailers"))))
+        self.assertEqual(data, (http2.HTTP2_DATA, http2.HTTP2_END_STREAM, 1,
+                                b"\x00\x00\x00\x00\x00"))
+
+    def test_settings_and_ping(self):
+        """The settings and pings of the server are acknowledged."""
+        server = FakeServer(self, [
+            http2.frame(http2.HTTP2_SETTINGS, 0, 0,
+                        bytes.fromhex("000300000064")),
+            http2.frame(http2.HTTP2_PING, 0, 0, b"12345678"),
+            http2.frame(http2.HTTP2_SETTINGS, http2.HTTP2_ACK, 0),
+            http2.frame(http2.HTTP2_HEADERS, http2.HTTP2_END_HEADERS, 1,
+                        RESPONSE_HEADERS),
+            http2.frame(http2.HTTP2_DATA, 0, 1, health_response(2)),
+            http2.frame(http2.HTTP2_HEADERS, http2.HTTP2_END_HEADERS |
+                        http2.HTTP2_END_STREAM, 1, TRAILERS)])
+        self.assertEqual(server.check(), "NOT_SERVING")
+        self.assertEqual(server.received[4:], [
+            (http2.HTTP2_SETTINGS, http2.HTTP2_ACK, 0, b""),
+            (http2.HTTP2_PING, http2.HTTP2_ACK, 0, b"12345678")])
+
+    def test_data_split(self):
+        """A response split across DATA frames is reassembled."""
+        response = health_response(3)
+        server = FakeServer(self, [
+            http2.frame(http2.HTTP2_HEADERS, http2.HTTP2_END_HEADERS, 1,
+                        RESPONSE_HEADERS),
+            http2.frame(http2.HTTP2_DATA, 0, 1, response[:3]),
+            http2.frame(http2.HTTP2_DATA, 0, 3, b"other stream"),
+            http2.frame(http2.HTTP2_DATA, http2.HTTP2_END_STREAM, 1,
+                        response[3:])])
+        self.assertEqual(server.check(), "SERVICE_UNKNOWN")
+
+    def test_unknown(self):
+        """A status omitted or out of range is UNKNOWN."""
+        for status in (None, 9):
+            server = FakeServer(self, [
+                http2.frame(http2.HTTP2_DATA, http2.HTTP2_END_STREAM, 1,
+                            health_response(status))])
+            self.assertEqual(server.check(), "UNKNOWN")
+
+    def test_trailers_only(self):
+        """A trailers-only response tells no status."""
+        server = FakeServer(self, [
+            http2.frame(http2.HTTP2_HEADERS, http2.HTTP2_END_HEADERS |
+                        http2.HTTP2_END_STREAM, 1, TRAILERS)])
+        with self.assertRaisesRegex(ValueError, "no health status"):
+            server.check()
+
+    def test_reset(self):
+        """A reset call is an error."""
+        server = FakeServer(self, [
+            http2.frame(http2.HTTP2_RST_STREAM, 0, 1,
+                        (8).to_bytes(4, "big"))])
+        with self.assertRaisesRegex(OSError, "error 8"):
+            server.check()
+
+    def test_goaway(self):
+        """A connection refused by the server is an error."""
+        server = FakeServer(self, [
+            http2.frame(http2.HTTP2_GOAWAY, 0, 0, bytes(8))])
+        with self.assertRaisesRegex(OSError, "GOAWAY"):
+            server.check()
+
+    def test_closed(self):
+        """A connection closed before the response is an error."""
+        server = FakeServer(self, [])
+        with self.assertRaisesRegex(OSError, "connection closed"):
+            server.check()
+
+
+if __name__ == "__main__":
+    unittest.main()
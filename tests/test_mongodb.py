@@ -0,0 +1,144 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Tests of the MongoDB commands and their BSON documents."""
+
+import socket
+import struct
+import threading
+import unittest
+
+from readiness import mongodb
+
+# examples of the BSON specification (bsonspec.org)
+HELLO_WORLD = (b"\x16\x00\x00\x00\x02hello\x00\x06\x00\x00\x00world\x00"
+               b"\x00")
+AWESOME = (b"\x31\x00\x00\x00\x04BSON\x00\x26\x00\x00\x00\x020\x00\x08\x00"
+           b"\x00\x00awesome\x00\x011\x00\x33\x33\x33\x33\x33\x33\x14\x40"
+           b"\x102\x00\xc2\x07\x00\x00\x00\x00")
+
+
+def element(kind, name, value):
+    """Encode a BSON element."""
+    return bytes([kind]) + name.encode() + b"\x00" + value
+
+
+def document(*elements):
+    """Encode a BSON document of encoded elements."""
+    body = b"".join(elements)
+    return struct.pack("<i", len(body) + 5) + body + b"\x00"
+
+
+class TestBson(unittest.TestCase):
+    """Tests of the encoding and decoding of the BSON documents."""
+
+    def test_encode(self):
+        """Strings, integers and booleans are encoded."""
+        self.assertEqual(mongodb.encode_bson({"hello": "world"}),
+                         HELLO_WORLD)
+        self.assertEqual(mongodb.encode_bson({"isMaster": 1, "a": True}),
+                         document(element(0x10, "isMaster",
+                                          struct.pack("<i", 1)),
+                                  element(0x08, "a", b"\x01")))
+
+    def test_decode(self):
+        """Arrays are decoded as lists."""
+        self.assertEqual(mongodb.decode_bson(HELLO_WORLD),
+                         {"hello": "world"})
+        self.assertEqual(mongodb.decode_bson(AWESOME),
+                         {"BSON": ["awesome", 5.05, 1986]})
+
+    def test_round_trip(self):
+        """The encoded documents decode to themselves."""
+        for value in ({}, {"ok": True, "n": -3, "setName": "rs0"},
+                      {"$db": "admin", "empty": "", "unicode": "é"}):
+            self.assertEqual(
+                mongodb.decode_bson(mongodb.encode_bson(value)), value)
+
+    def test_decode_types(self):
+        """The types of the responses of the servers are decoded."""
+        data = document(
+            element(0x01, "double", struct.pack("<d", 1.5)),
+            element(0x03, "document", HELLO_WORLD),
+            element(0x05, "binary", struct.pack("<i", 2) + b"\x00ab"),
+            element(0x07, "oid", bytes(range(12))),
+            element(0x08, "false", b"\x00"),
+            element(0x09, "date", struct.pack("<q", 1700000000000)),
+            element(0x0A, "null", b""),
+            element(0x11, "timestamp", struct.pack("<Q", 2 ** 40)),
+            element(0x12, "long", struct.pack("<q", -2 ** 40)),
+            element(0x13, "decimal", bytes(16)),
+            element(0x10, "int", struct.pack("<i", 7)))
+        self.assertEqual(mongodb.decode_bson(b"xx" + data, 2), {
+            "double": 1.5, "document": {"hello": "world"}, "binary": b"ab",
+            "oid": "000102030405060708090a0b", "false": False,
+            "date": 1700000000000, "null": None, "timestamp": 2 ** 40,
+            "long": -2 ** 40, "decimal": bytes(16), "int": 7})
+
+    def test_unsupported(self):
+        """An unsupported type is an error."""
+        with self.assertRaisesRegex(ValueError, "0xb"):
+            mongodb.decode_bson(document(element(0x0B, "regex", b"a\0\0")))
+
+    def test_truncated(self):
+        """A truncated document is an error."""
+        with self.assertRaises(struct.error):
+            mongodb.decode_bson(document(element(0x12, "long", b"\x01")))
+
+
+class TestCommand(unittest.TestCase):
+    """Tests of the OP_MSG commands."""
+
+    def serve(self, response):
+        client, server = socket.socketpair()
+        self.addCleanup(client.close)
+        requests = []
+
+        def serve():
+            with server:
+                length = struct.unpack("<i", server.recv(4))[0]
+                requests.append(mongodb.receive(server, length - 4))
+                for offset in range(0, len(response), 7):
+                    server.sendall(response[offset:offset + 7])
+
+        thread = threading.Thread(target=serve)
+        thread.start()
+        self.addCleanup(thread.join)
+        return client, requests
+
+    def test_command(self):
+        """The command is run on the admin database."""
+        reply = document(element(0x08, "ismaster", b"\x01"),
+                         element(0x01, "ok", struct.pack("<d", 1)))
+        response = struct.pack("<iiiiI", 21 + len(reply), 2, 1,
+                               mongodb.MONGODB_OP_MSG, 0) + b"\x00" + reply
+        client, requests = self.serve(response)
+        self.assertEqual(mongodb.command(client, {"isMaster": 1}),
+                         {"ismaster": True, "ok": 1.0})
+        body = mongodb.encode_bson({"isMaster": 1, "$db": "admin"})
+        self.assertEqual(requests, [
+            struct.pack("<iiiI", 1, 0, mongodb.MONGODB_OP_MSG, 0) +
+            b"\x00" + body])
+
+    def test_closed(self):
+        """A connection closed before the end of the response is an error."""
+        client, _ = self.serve(struct.pack("<i", 100) + b"short")
+        with self.assertRaisesRegex(OSError, "closed"):
+            mongodb.command(client, {"isMaster": 1})
+
+
+if __name__ == "__main__":
+    unittest.main()
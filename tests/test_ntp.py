@@ -0,0 +1,72 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Tests of the SNTP queries."""
+
+import socket
+import struct
+import threading
+import time
+import unittest
+
+from readiness import ntp
+
+
+def timestamp(value):
+    """Encode an NTP timestamp."""
+    value += ntp.NTP_EPOCH_OFFSET
+    return struct.pack("!II", int(value), int(value % 1 * 2 ** 32))
+
+
+class TestNtp(unittest.TestCase):
+    """Tests of the offset of the local clock."""
+
+    def test_parse_offset(self):
+        """The offset is the mean of the two clock differences."""
+        response = (32 * b"\0" + timestamp(1000.5) + timestamp(1001.5))
+        self.assertAlmostEqual(ntp.parse_offset(response, 990, 992), 10,
+                               places=6)
+
+    def test_short_answer(self):
+        """An answer shorter than an NTP packet is rejected."""
+        with self.assertRaises(ValueError):
+            ntp.parse_offset(40 * b"\0", 0, 0)
+
+    def test_get_offset(self):
+        """The server is queried with a client request."""
+        server = socket.socket(socket.AF_INET, socket.SOCK_DGRAM)
+        self.addCleanup(server.close)
+        server.bind(("127.0.0.1", 0))
+        requests = []
+
+        def serve():
+            request, client = server.recvfrom(512)
+            requests.append(request)
+            now = time.time() + 30
+            server.sendto(request[:32] + timestamp(now) + timestamp(now),
+                          client)
+
+        thread = threading.Thread(target=serve)
+        thread.start()
+        offset = ntp.get_offset("127.0.0.1", server.getsockname()[1],
+                                socket.AF_INET)
+        thread.join()
+        self.assertEqual(requests, [ntp.NTP_REQUEST])
+        self.assertAlmostEqual(offset, 30, delta=1)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,94 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Tests of the parsers of the options and of the resources."""
+
+import unittest
+
+import ready
+
+
+class TestParsers(unittest.TestCase):
+    """Tests of the parsers."""
+
+    def test_matches_selector_string(self):
+        """Equality, inequality and existence requirements are matched."""
+        labels = {"app": "so", "tier": "api"}
+        self.assertTrue(ready.matches_selector_string("app=so", labels))
+        self.assertTrue(ready.matches_selector_string("app==so,tier",
+                                                      labels))
+        self.assertTrue(ready.matches_selector_string("tier!=db,!canary",
+                                                      labels))
+        self.assertFalse(ready.matches_selector_string("app=sdc", labels))
+        self.assertFalse(ready.matches_selector_string("!tier", labels))
+        self.assertFalse(ready.matches_selector_string("app", None))
+
+
+    def test_parse_quantity(self):
+        """The suffixes and exponents scale the quantities."""
+        self.assertEqual(ready.parse_quantity("500m"), 0.5)
+        self.assertEqual(ready.parse_quantity("4Gi"), 4 * 2 ** 30)
+        self.assertEqual(ready.parse_quantity("2k"), 2000)
+        self.assertEqual(ready.parse_quantity("1e3"), 1000)
+        self.assertEqual(ready.parse_quantity("3"), 3)
+        for quantity in ("", "Gi", "4GB", "1.2.3"):
+            with self.assertRaises(ValueError):
+                ready.parse_quantity(quantity)
+
+    def test_split_host_port(self):
+        """IPv6 literals are bracketed when followed by a port."""
+        self.assertEqual(ready.split_host_port("zk", 2181), ("zk", 2181))
+        self.assertEqual(ready.split_host_port("zk:2182", 2181),
+                         ("zk", 2182))
+        self.assertEqual(ready.split_host_port("[fd00::1]:2182", 2181),
+                         ("fd00::1", 2182))
+        self.assertEqual(ready.split_host_port("fd00::1", 2181),
+                         ("fd00::1", 2181))
+        for address in ("", ":1", "zk:x", "[fd00::1", "[fd00::1]x"):
+            with self.assertRaises(ValueError):
+                ready.split_host_port(address, 2181)
+
+    def test_parse_indexes(self):
+        """Single indexes and intervals are merged in a set."""
+        self.assertEqual(ready.parse_indexes("1,3-5"), {1, 3, 4, 5})
+        self.assertEqual(ready.parse_indexes(""), set())
+        with self.assertRaises(ValueError):
+            ready.parse_indexes("a-b")
+
+    def test_parse_delay(self):
+        """The delays are in seconds unless suffixed with m."""
+        self.assertEqual(ready.parse_delay("30"), 30)
+        self.assertEqual(ready.parse_delay("30s"), 30)
+        self.assertEqual(ready.parse_delay("1.5m"), 90)
+        for delay in ("", "m", "5h", "-1"):
+            with self.assertRaises(ValueError):
+                ready.parse_delay(delay)
+
+    def test_parse_image(self):
+        """The registry defaults to Docker Hub and the tag to latest."""
+        self.assertEqual(ready.parse_image("busybox"),
+                         (ready.DOCKER_HUB, "library/busybox", "latest"))
+        self.assertEqual(ready.parse_image("onap/so:1.2"),
+                         (ready.DOCKER_HUB, "onap/so", "1.2"))
+        self.assertEqual(
+            ready.parse_image("nexus3.onap.org:10001/onap/so@sha256:ab"),
+            ("nexus3.onap.org:10001", "onap/so", "sha256:ab"))
+        self.assertEqual(ready.parse_image("localhost/so:1"),
+                         ("localhost", "so", "1"))
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,161 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Tests of the readiness predicates of the containers, Jobs and Services."""
+
+import unittest
+from types import SimpleNamespace
+from unittest import mock
+
+from kubernetes.client.rest import ApiException
+
+import ready
+
+
+def job(succeeded=0, conditions=(), suspend=None):
+    """Build a Job as read from the API."""
+    return SimpleNamespace(
+        metadata=SimpleNamespace(name="job", uid="1",
+                                 creation_timestamp=None),
+        spec=SimpleNamespace(suspend=suspend, completion_mode=None),
+        status=SimpleNamespace(succeeded=succeeded, conditions=[
+            SimpleNamespace(type=kind, status="True")
+            for kind in conditions]))
+
+
+def pod(container_name, owner_kind, owner_name):
+    """Build a running pod owned by a workload."""
+    return SimpleNamespace(
+        metadata=SimpleNamespace(
+            name=owner_name + "-x", namespace="onap",
+            deletion_timestamp=None,
+            owner_references=[SimpleNamespace(kind=owner_kind,
+                                              name=owner_name)]),
+        status=SimpleNamespace(
+            phase="Running", reason=None, conditions=[],
+            container_statuses=[SimpleNamespace(
+                name=container_name, ready=True, restart_count=0,
+                last_state=None)]))
+
+
+def endpoints(ready_addresses, not_ready_addresses=0, port="http"):
+    """Build Endpoints with a subset serving a port."""
+    return SimpleNamespace(subsets=[SimpleNamespace(
+        addresses=[object()] * ready_addresses,
+        not_ready_addresses=[object()] * not_ready_addresses,
+        ports=[SimpleNamespace(name=port, port=80)])])
+
+
+class PredicateTestCase(unittest.TestCase):
+    """Base of the tests, with the API clients mocked."""
+
+    def setUp(self):
+        ready.use_options(ready.Options())
+        self.core = mock.Mock()
+        self.batch = mock.Mock()
+        for name, value in (("coreV1Api", self.core),
+                            ("batchV1Api", self.batch)):
+            patcher = mock.patch.object(ready, name, value)
+            patcher.start()
+            self.addCleanup(patcher.stop)
+
+
+class TestIsReady(PredicateTestCase):
+    """Tests of the readiness of the containers."""
+
+    def test_job_owner(self):
+        """A container of a Job is ready once the Job is complete."""
+        self.core.list_namespaced_pod.return_value = SimpleNamespace(
+            items=[pod("so-init", "Job", "so-init-job")])
+        self.batch.read_namespaced_job_status.return_value = job(
+            1, ["Complete"])
+        self.assertTrue(ready.is_ready("so-init", "onap"))
+        self.batch.read_namespaced_job_status.assert_called_once_with(
+            "so-init-job", "onap")
+
+    def test_no_pod(self):
+        """A container without pod is not ready."""
+        self.core.list_namespaced_pod.return_value = SimpleNamespace(
+            items=[pod("sdc", "Job", "sdc-job")])
+        self.assertFalse(ready.is_ready("so", "onap"))
+        self.batch.read_namespaced_job_status.assert_not_called()
+
+    def test_unsupported_owner(self):
+        """A container of an unsupported workload is not ready."""
+        self.core.list_namespaced_pod.return_value = SimpleNamespace(
+            items=[pod("so", "CronJob", "so")])
+        self.assertFalse(ready.is_ready("so", "onap"))
+
+
+class TestIsServiceReady(PredicateTestCase):
+    """Tests of the readiness of the Services."""
+
+    def setUp(self):
+        super().setUp()
+        self.core.read_namespaced_service.return_value = SimpleNamespace(
+            spec=SimpleNamespace(ports=[SimpleNamespace(name="http",
+                                                        port=80)]))
+
+    def test_ready(self):
+        """A Service with ready endpoints is ready."""
+        self.core.read_namespaced_endpoints.return_value = endpoints(1)
+        self.assertTrue(ready.is_service_ready("so", "onap"))
+        self.assertTrue(ready.is_service_ready("so:http", "onap"))
+
+    def test_not_ready(self):
+        """A Service with not ready endpoints only is not ready."""
+        self.core.read_namespaced_endpoints.return_value = endpoints(0, 2)
+        self.assertFalse(ready.is_service_ready("so", "onap"))
+
+    def test_missing_port(self):
+        """A port not declared by the Service is never ready."""
+        self.core.read_namespaced_endpoints.return_value = endpoints(1)
+        self.assertFalse(ready.is_service_ready("so:grpc", "onap"))
+        self.core.read_namespaced_endpoints.assert_not_called()
+
+
+class TestIsJobComplete(PredicateTestCase):
+    """Tests of the completion of the Jobs."""
+
+    def test_complete(self):
+        """A succeeded Job with a Complete condition is complete."""
+        self.batch.read_namespaced_job_status.return_value = job(
+            1, ["Complete"])
+        self.assertTrue(ready.is_job_complete("job", "onap"))
+        self.batch.read_namespaced_job_status.assert_called_once_with(
+            "job", "onap")
+
+    def test_running(self):
+        """A Job which has not succeeded yet is not complete."""
+        self.batch.read_namespaced_job_status.return_value = job()
+        self.assertFalse(ready.is_job_complete("job", "onap"))
+
+    def test_suspended(self):
+        """A suspended Job is not complete."""
+        self.batch.read_namespaced_job_status.return_value = job(
+            suspend=True)
+        with mock.patch.object(ready, "report_suspension"):
+            self.assertFalse(ready.is_job_complete("job", "onap"))
+
+    def test_api_error(self):
+        """A failing API call is not a completion."""
+        self.batch.read_namespaced_job_status.side_effect = ApiException(
+            500)
+        self.assertFalse(ready.is_job_complete("job", "onap"))
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,161 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Tests of the certificates of the PEM bundles and JKS truststores."""
+
+import base64
+import datetime
+import os
+import struct
+import unittest
+from types import SimpleNamespace
+from unittest import mock
+
+import ready
+from readiness import x509
+
+FIXTURES = os.path.join(os.path.dirname(__file__), "fixtures")
+
+
+def fixture(name):
+    """Read a fixture."""
+    with open(os.path.join(FIXTURES, name), "rb") as data:
+        return data.read()
+
+
+def timestamp(*date):
+    """Return the timestamp of a UTC date."""
+    return datetime.datetime(*date,
+                             tzinfo=datetime.timezone.utc).timestamp()
+
+
+# common names and validities of the certificates of the fixtures, as
+# printed by openssl x509 -subject -dates
+ONAP_CA = ("onap-ca", timestamp(2026, 10, 15, 5, 21, 41),
+           timestamp(2126, 9, 21, 5, 21, 41))
+SDNC = ("sdnc", timestamp(1999, 1, 1), timestamp(2049, 12, 31, 23, 59, 59))
+NO_CN = (None, timestamp(2026, 10, 15, 5, 21, 42),
+         timestamp(2036, 10, 12, 5, 21, 42))
+
+
+class TestDer(unittest.TestCase):
+    """Tests of the headers of the DER elements."""
+
+    def test_read_der(self):
+        """Short and long lengths are read."""
+        self.assertEqual(x509.read_der(b"\x04\x02ab", 0), (4, 2, 4))
+        data = b"\x30\x81\x80" + bytes(0x80)
+        self.assertEqual(x509.read_der(data, 0), (0x30, 3, 0x83))
+        data = b"\x00\x30\x82\x01\x00" + bytes(0x100)
+        self.assertEqual(x509.read_der(data, 1), (0x30, 5, 0x105))
+
+    def test_truncated(self):
+        """An element longer than the data is an error."""
+        with self.assertRaisesRegex(ValueError, "truncated"):
+            x509.read_der(b"\x04\x03ab", 0)
+
+
+class TestCertificates(unittest.TestCase):
+    """Tests of the common names and validities of the certificates."""
+
+    def read(self, name):
+        return [x509.parse_certificate(der) for der in
+                x509.read_truststore_certificates(fixture(name))]
+
+    def test_pem(self):
+        """UTCTime and GeneralizedTime validities are read."""
+        self.assertEqual(self.read("ca.pem"), [ONAP_CA])
+        self.assertEqual(self.read("bundle.pem"), [SDNC, ONAP_CA])
+
+    def test_no_common_name(self):
+        """A subject without common name has none."""
+        self.assertEqual(self.read("no-cn.pem"), [NO_CN])
+
+    def test_jks(self):
+        """The trusted certificates and the key chains are read."""
+        self.assertEqual(self.read("truststore.jks"),
+                         [ONAP_CA, SDNC, ONAP_CA])
+
+    def test_jks_version_1(self):
+        """The certificates of version 1 truststores have no type."""
+        der = base64.b64decode(b"".join(fixture("ca.pem").splitlines()[1:-1]))
+        store = (x509.JKS_MAGIC + struct.pack("!II", 1, 1) +
+                 struct.pack("!IH", x509.JKS_TRUSTED_CERTIFICATE, 2) + b"ca" +
+                 bytes(8) + struct.pack("!I", len(der)) + der + bytes(20))
+        self.assertEqual(x509.read_truststore_certificates(store), [der])
+
+    def test_jks_malformed(self):
+        """Unknown entries and truncated truststores are errors."""
+        store = fixture("truststore.jks")
+        with self.assertRaisesRegex(ValueError, "entry type 3"):
+            x509.read_truststore_certificates(
+                store[:12] + struct.pack("!I", 3) + store[16:])
+        with self.assertRaises(struct.error):
+            x509.read_truststore_certificates(store[:40])
+
+    def test_empty_pem(self):
+        """A PEM bundle without certificate has none."""
+        self.assertEqual(x509.read_truststore_certificates(b"key\n"), [])
+
+
+class TestHasTruststoreCas(unittest.TestCase):
+    """Tests of the truststores checks."""
+
+    def setUp(self):
+        ready.use_options(ready.Options())
+        patcher = mock.patch.object(ready, "coreV1Api")
+        self.core = patcher.start()
+        self.addCleanup(patcher.stop)
+
+    def secret(self, name):
+        self.core.read_namespaced_secret.return_value = SimpleNamespace(
+            data={"truststore": base64.b64encode(fixture(name)).decode()})
+
+    def test_ready(self):
+        """The truststore includes valid certificates of the CAs."""
+        for name in ("bundle.pem", "truststore.jks"):
+            self.secret(name)
+            with mock.patch.object(ready.time, "time",
+                                   return_value=timestamp(2030, 1, 1)):
+                self.assertTrue(ready.has_truststore_cas(
+                    "certs/truststore=onap-ca,sdnc", "onap"))
+        self.core.read_namespaced_secret.assert_called_with("certs", "onap")
+
+    def test_missing_ca(self):
+        """A CA without certificate is missing."""
+        self.secret("ca.pem")
+        self.assertFalse(ready.has_truststore_cas(
+            "certs/truststore=onap-ca,sdnc", "onap"))
+
+    def test_expired(self):
+        """A CA whose certificates expired is missing."""
+        self.secret("bundle.pem")
+        with mock.patch.object(ready.time, "time",
+                               return_value=timestamp(2050, 1, 1)):
+            self.assertFalse(ready.has_truststore_cas(
+                "certs/truststore=sdnc", "onap"))
+
+    def test_invalid(self):
+        """A malformed truststore is not ready."""
+        self.core.read_namespaced_secret.return_value = SimpleNamespace(
+            data={"truststore": base64.b64encode(
+                fixture("truststore.jks")[:40]).decode()})
+        self.assertFalse(ready.has_truststore_cas(
+            "certs/truststore=onap-ca", "onap"))
+
+
+if __name__ == "__main__":
+    unittest.main()
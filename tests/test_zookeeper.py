@@ -0,0 +1,65 @@
+# -*- coding: utf-8 -*-
+# Copyright © 2020 Orange
+# Copyright © 2020 Nokia
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#       http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Tests of the ZooKeeper four-letter words."""
+
+import socket
+import threading
+import unittest
+
+from readiness import zookeeper
+
+SRVR = (b"Zookeeper version: 3.8.4\nLatency min/avg/max: 0/0.0/0\n"
+        b"Received: 12\nSent: 11\nConnections: 1\nOutstanding: 0\n"
+        b"Zxid: 0x100000000\nMode: follower\nNode count: 5\n")
+
+
+class TestZookeeper(unittest.TestCase):
+    """Tests of the mode of the servers."""
+
+    def test_parse_mode(self):
+        """The mode is read from the srvr response."""
+        self.assertEqual(zookeeper.parse_mode(SRVR), "follower")
+
+    def test_not_serving(self):
+        """A server not serving requests has no mode."""
+        with self.assertRaisesRegex(ValueError, "not currently serving"):
+            zookeeper.parse_mode(b"This ZooKeeper instance is not currently"
+                                 b" serving requests\n")
+        with self.assertRaisesRegex(ValueError, "empty response"):
+            zookeeper.parse_mode(b"")
+
+    def test_server_mode(self):
+        """srvr is sent and the response read until the connection ends."""
+        client, server = socket.socketpair()
+        self.addCleanup(client.close)
+        requests = []
+
+        def serve():
+            with server:
+                requests.append(server.recv(4))
+                for line in SRVR.splitlines(True):
+                    server.sendall(line)
+
+        thread = threading.Thread(target=serve)
+        thread.start()
+        self.assertEqual(zookeeper.server_mode(client), "follower")
+        thread.join()
+        self.assertEqual(requests, [b"srvr"])
+
+
+if __name__ == "__main__":
+    unittest.main()